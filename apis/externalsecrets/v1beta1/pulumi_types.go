@@ -0,0 +1,54 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	esmeta "github.com/external-secrets/external-secrets/apis/meta/v1"
+)
+
+// PulumiProvider configures a store to sync secrets using the Pulumi Cloud.
+type PulumiProvider struct {
+	// AccessToken is the access token to sign in to the Pulumi Cloud Console.
+	AccessToken esmeta.SecretKeySelector `json:"accessToken"`
+
+	// Organization are a collection of related projects, often representing an
+	// organizational structure or team, read more here:
+	// https://www.pulumi.com/docs/pulumi-cloud/organizations/
+	Organization string `json:"organization"`
+
+	// Environment is the Pulumi ESC environment to use, read more here:
+	// https://www.pulumi.com/docs/esc/environments/
+	// Deprecated: use Environments instead. If both are set, Environment is
+	// appended to the end of Environments.
+	// +optional
+	Environment string `json:"environment,omitempty"`
+
+	// Environments is an ordered list of Pulumi ESC environments to read
+	// secrets from. Environments are opened in order and the first one that
+	// defines a requested key wins, which mirrors how Pulumi ESC composes
+	// environments via `imports`. GetAllSecrets merges matching keys across
+	// all environments, with earlier environments taking precedence.
+	// +optional
+	Environments []string `json:"environments,omitempty"`
+
+	// CacheTTL is the duration for which an opened environment's decoded
+	// values are cached and reused across the keys of a single reconcile,
+	// instead of issuing a fresh OpenEnvironment call per key.
+	// Defaults to 30s.
+	// +optional
+	CacheTTL *metav1.Duration `json:"cacheTTL,omitempty"`
+}
@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulumi
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is used when a SecretStore does not set an explicit
+// CacheTTL for its Pulumi ESC provider.
+const defaultCacheTTL = 30 * time.Second
+
+// envCacheEntry holds the result of a single OpenAndReadEnvironment call so
+// that reading several keys from the same environment during one reconcile
+// only opens it once.
+type envCacheEntry struct {
+	id        string
+	values    map[string]interface{}
+	expiresAt time.Time
+}
+
+// fetchEnvironmentFunc fetches and decodes a Pulumi ESC environment. It is the
+// seam envCache.get calls on a miss, kept as a plain function type so tests
+// can stub it without a live Pulumi Cloud endpoint.
+type fetchEnvironmentFunc func() (id string, values map[string]interface{}, err error)
+
+// envCache is a short-lived, per-client cache of opened Pulumi ESC
+// environments, keyed by "organization/environment". It exists so that an
+// ExternalSecret with N data entries issues one OpenAndReadEnvironment call
+// per environment instead of N.
+type envCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*envCacheEntry
+}
+
+func newEnvCache(ttl time.Duration) *envCache {
+	return &envCache{
+		ttl:     ttl,
+		entries: make(map[string]*envCacheEntry),
+	}
+}
+
+// get returns the cached entry for key if one exists and hasn't expired;
+// otherwise it calls fetch, caches the result, and returns it.
+func (c *envCache) get(key string, fetch fetchEnvironmentFunc) (*envCacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		cacheHitsTotal.Inc()
+		return entry, nil
+	}
+	cacheMissesTotal.Inc()
+
+	id, values, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &envCacheEntry{
+		id:        id,
+		values:    values,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+	c.entries[key] = entry
+	return entry, nil
+}
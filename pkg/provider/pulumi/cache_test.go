@@ -0,0 +1,91 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulumi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnvCacheGetHitsAndMisses(t *testing.T) {
+	cache := newEnvCache(50 * time.Millisecond)
+
+	calls := 0
+	fetch := func() (string, map[string]interface{}, error) {
+		calls++
+		return "env-id", map[string]interface{}{"call": calls}, nil
+	}
+
+	first, err := cache.get("org/env", fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the first lookup to fetch, got %d calls", calls)
+	}
+
+	second, err := cache.get("org/env", fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a cache hit within the TTL window to avoid a re-fetch, got %d calls", calls)
+	}
+	if second != first {
+		t.Fatalf("expected the cached entry to be reused, got a different entry")
+	}
+
+	time.Sleep(2 * cache.ttl)
+
+	if _, err := cache.get("org/env", fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected an expired entry to trigger a re-fetch, got %d calls", calls)
+	}
+}
+
+func TestEnvCacheGetIsPerKey(t *testing.T) {
+	cache := newEnvCache(time.Minute)
+
+	calls := 0
+	fetch := func() (string, map[string]interface{}, error) {
+		calls++
+		return "env-id", map[string]interface{}{}, nil
+	}
+
+	if _, err := cache.get("org/a", fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cache.get("org/b", fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected distinct keys to fetch independently, got %d calls", calls)
+	}
+}
+
+func TestEnvCacheGetFetchError(t *testing.T) {
+	cache := newEnvCache(time.Minute)
+	wantErr := errors.New("boom")
+
+	_, err := cache.get("org/env", func() (string, map[string]interface{}, error) {
+		return "", nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected the fetch error to propagate, got %v", err)
+	}
+}
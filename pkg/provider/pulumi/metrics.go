@@ -0,0 +1,36 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulumi
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/external-secrets/external-secrets/pkg/provider/metrics"
+)
+
+var (
+	cacheHitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pulumi_esc_environment_cache_hits_total",
+		Help: "Total number of Pulumi ESC environment reads served from the in-memory cache.",
+	})
+	cacheMissesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pulumi_esc_environment_cache_misses_total",
+		Help: "Total number of Pulumi ESC environment reads that required an OpenAndReadEnvironment call.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cacheHitsTotal, cacheMissesTotal)
+}
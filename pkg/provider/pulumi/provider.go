@@ -0,0 +1,91 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulumi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	esc "github.com/pulumi/esc-sdk/sdk/go"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	esv1beta1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1beta1"
+	"github.com/external-secrets/external-secrets/pkg/utils/resolvers"
+)
+
+const (
+	errPulumiStore  = "received invalid Pulumi SecretStore resource"
+	errPulumiClient = "could not create Pulumi ESC client: %w"
+)
+
+// Provider is a secrets provider for Pulumi ESC.
+type Provider struct{}
+
+var _ esv1beta1.Provider = &Provider{}
+
+// NewClient constructs a Pulumi ESC client from the SecretStore spec.
+func (p *Provider) NewClient(ctx context.Context, store esv1beta1.GenericStore, kube client.Client, namespace string) (esv1beta1.SecretsClient, error) {
+	storeSpec := store.GetSpec()
+	if storeSpec == nil || storeSpec.Provider == nil || storeSpec.Provider.Pulumi == nil {
+		return nil, errors.New(errPulumiStore)
+	}
+	pulumiSpec := storeSpec.Provider.Pulumi
+
+	accessToken, err := resolvers.SecretKeyRef(ctx, kube, store.GetKind(), namespace, &pulumiSpec.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf(errPulumiClient, err)
+	}
+
+	// copy before appending: pulumiSpec.Environments is a slice into the
+	// GenericStore returned by store.GetSpec(), which is typically backed by
+	// the controller-runtime informer cache and shared across reconciles.
+	// Appending in place could silently mutate that shared backing array.
+	environments := append([]string{}, pulumiSpec.Environments...)
+	if pulumiSpec.Environment != "" {
+		environments = append(environments, pulumiSpec.Environment)
+	}
+	if len(environments) == 0 {
+		return nil, errors.New(errPulumiStore)
+	}
+
+	cfg := esc.NewConfiguration()
+	escClient := esc.NewClient(cfg)
+	authCtx := context.WithValue(ctx, esc.ContextAccessToken, accessToken)
+
+	cacheTTL := defaultCacheTTL
+	if pulumiSpec.CacheTTL != nil {
+		cacheTTL = pulumiSpec.CacheTTL.Duration
+	}
+
+	return &client{
+		escClient:    *escClient,
+		authCtx:      authCtx,
+		organization: pulumiSpec.Organization,
+		environments: environments,
+		cache:        newEnvCache(cacheTTL),
+	}, nil
+}
+
+// Capabilities returns the provider's supported operations.
+func (p *Provider) Capabilities() esv1beta1.SecretStoreCapabilities {
+	return esv1beta1.SecretStoreReadWrite
+}
+
+func init() {
+	esv1beta1.Register(&Provider{}, &esv1beta1.SecretStoreProvider{
+		Pulumi: &esv1beta1.PulumiProvider{},
+	}, esv1beta1.MaintenanceStatusMaintained)
+}
@@ -15,9 +15,12 @@ limitations under the License.
 package pulumi
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
 
 	esc "github.com/pulumi/esc-sdk/sdk/go"
@@ -30,50 +33,117 @@ import (
 type client struct {
 	escClient    esc.EscClient
 	authCtx      context.Context
-	environment  string
+	environments []string
 	organization string
+
+	cache *envCache
 }
 
 const (
-	errPushSecretsNotSupported       = "pushing secrets is currently not supported by Pulumi"
-	errDeleteSecretsNotSupported     = "deleting secrets is currently not supported by Pulumi"
-	errUnableToGetValues             = "unable to get value for key %s: %w"
-	errGettingAllSecretsNotSupported = "getting all secrets is currently not supported by Pulumi"
-	errReadEnvironment               = "error reading environment : %w"
-	errPushSecrets                   = "error pushing secret: %w"
-	errInterfaceType                 = "interface{} is not of type map[string]interface{}"
+	errUnableToGetValues     = "unable to get value for key %s: %w"
+	errKeyNotFound           = "key %s not found in environment"
+	errReadEnvironment       = "error reading environment : %w"
+	errPushSecrets           = "error pushing secret: %w"
+	errDeleteSecret          = "error deleting secret: %w"
+	errInterfaceType         = "interface{} is not of type map[string]interface{}"
+	errEnvironmentNotInStore = "environment %q is not one of the store's configured environments"
 )
 
 var _ esv1beta1.SecretsClient = &client{}
 
+// primaryEnvironment is the environment that push/delete operations target:
+// the first environment in the composition order.
+func (c *client) primaryEnvironment() string {
+	return c.environments[0]
+}
+
+// environmentsForRef returns the ordered list of environments to search for
+// ref. An explicit ref.Version overrides the store's environment composition
+// with a single named environment, allowing a single ExternalSecret to pull
+// individual keys from different ESC environments. The override is rejected
+// if it names an environment outside the store's configured list: the
+// SecretStore's environments are the trust boundary, and an ExternalSecret
+// referencing the store must not be able to widen it to an arbitrary
+// environment the store's access token happens to be able to see.
+func (c *client) environmentsForRef(ref esv1beta1.ExternalSecretDataRemoteRef) ([]string, error) {
+	if ref.Version == "" {
+		return c.environments, nil
+	}
+	for _, environment := range c.environments {
+		if environment == ref.Version {
+			return []string{environment}, nil
+		}
+	}
+	return nil, fmt.Errorf(errEnvironmentNotInStore, ref.Version)
+}
+
+// openEnvironment returns the cached (environment id, decoded values) pair for
+// organization+environment, refreshing it with a single OpenAndReadEnvironment
+// call when the entry is missing or has expired.
+func (c *client) openEnvironment(environment string) (*envCacheEntry, error) {
+	cacheKey := c.organization + "/" + environment
+	return c.cache.get(cacheKey, func() (string, map[string]interface{}, error) {
+		openEnv, values, err := c.escClient.OpenAndReadEnvironment(c.authCtx, c.organization, environment)
+		if err != nil {
+			return "", nil, err
+		}
+		return openEnv.GetId(), values, nil
+	})
+}
+
 func (c *client) GetSecret(_ context.Context, ref esv1beta1.ExternalSecretDataRemoteRef) ([]byte, error) {
-	env, err := c.escClient.OpenEnvironment(c.authCtx, c.organization, c.environment)
+	environments, err := c.environmentsForRef(ref)
 	if err != nil {
 		return nil, err
 	}
-	value, _, err := c.escClient.ReadEnvironmentProperty(c.authCtx, c.organization, c.environment, env.GetId(), ref.Key)
-	if err != nil {
-		return nil, err
+
+	var lastErr error
+	for _, environment := range environments {
+		entry, err := c.openEnvironment(environment)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		value, ok := getNestedValue(entry.values, strings.Split(ref.Key, "."))
+		if !ok {
+			lastErr = fmt.Errorf(errKeyNotFound, ref.Key)
+			continue
+		}
+		return utils.GetByteValue(value)
 	}
-	return utils.GetByteValue(value.GetValue())
+	return nil, lastErr
 }
 
 func (c *client) PushSecret(_ context.Context, secret *corev1.Secret, data esv1beta1.PushSecretData) error {
 	value := secret.Data[data.GetSecretKey()]
 
+	key := data.GetRemoteKey()
+	if data.GetProperty() != "" {
+		key = fmt.Sprintf("%s.%s", key, data.GetProperty())
+	}
+
+	_, oldValues, err := c.escClient.OpenAndReadEnvironment(c.authCtx, c.organization, c.primaryEnvironment())
+	if err != nil {
+		return fmt.Errorf(errReadEnvironment, err)
+	}
+
+	if existing, ok := getNestedValue(oldValues, strings.Split(key, ".")); ok {
+		existingValue, err := utils.GetByteValue(existing)
+		if err == nil && bytes.Equal(existingValue, value) {
+			// the remote value already matches the desired value, nothing to do.
+			return nil
+		}
+	}
+
 	updatePayload := &esc.EnvironmentDefinition{
 		Values: &esc.EnvironmentDefinitionValues{
 			AdditionalProperties: map[string]interface{}{
-				data.GetRemoteKey(): string(value),
+				key: string(value),
 			},
 		},
 	}
-	_, oldValues, err := c.escClient.OpenAndReadEnvironment(c.authCtx, c.organization, c.environment)
-	if err != nil {
-		return fmt.Errorf(errReadEnvironment, err)
-	}
 	updatePayload.Values.AdditionalProperties = mergeMaps(oldValues, updatePayload.Values.AdditionalProperties)
-	_, err = c.escClient.UpdateEnvironment(c.authCtx, c.organization, c.environment, updatePayload)
+	_, err = c.escClient.UpdateEnvironment(c.authCtx, c.organization, c.primaryEnvironment(), updatePayload)
 	if err != nil {
 		return fmt.Errorf(errPushSecrets, err)
 	}
@@ -127,12 +197,85 @@ func mergeMaps(map1, map2 map[string]interface{}) map[string]interface{} {
 	return mergedMap
 }
 
-func (c *client) SecretExists(_ context.Context, _ esv1beta1.PushSecretRemoteRef) (bool, error) {
-	return false, errors.New(errPushSecretsNotSupported)
+// getNestedValue looks up a (possibly dotted) key path inside a decoded
+// environment values map, mirroring the nesting rules applied by mergeMaps.
+func getNestedValue(m map[string]interface{}, keys []string) (interface{}, bool) {
+	if len(keys) == 0 {
+		return nil, false
+	}
+	value, ok := m[keys[0]]
+	if !ok {
+		return nil, false
+	}
+	if len(keys) == 1 {
+		return value, true
+	}
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return getNestedValue(nested, keys[1:])
+}
+
+// removeNestedKey deletes a (possibly dotted) key path from a decoded
+// environment values map, leaving sibling keys untouched.
+func removeNestedKey(m map[string]interface{}, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if len(keys) == 1 {
+		delete(m, keys[0])
+		return
+	}
+	nested, ok := m[keys[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	removeNestedKey(nested, keys[1:])
+}
+
+// isNotFoundError reports whether resp represents an HTTP 404, which the ESC
+// API returns when a property or environment does not exist.
+func isNotFoundError(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
 }
 
-func (c *client) DeleteSecret(_ context.Context, _ esv1beta1.PushSecretRemoteRef) error {
-	return errors.New(errDeleteSecretsNotSupported)
+func (c *client) SecretExists(_ context.Context, ref esv1beta1.PushSecretRemoteRef) (bool, error) {
+	environment := c.primaryEnvironment()
+	env, err := c.escClient.OpenEnvironment(c.authCtx, c.organization, environment)
+	if err != nil {
+		return false, err
+	}
+	_, httpResp, err := c.escClient.ReadEnvironmentProperty(c.authCtx, c.organization, environment, env.GetId(), ref.GetRemoteKey())
+	if err != nil {
+		if isNotFoundError(httpResp) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *client) DeleteSecret(_ context.Context, ref esv1beta1.PushSecretRemoteRef) error {
+	environment := c.primaryEnvironment()
+	_, oldValues, err := c.escClient.OpenAndReadEnvironment(c.authCtx, c.organization, environment)
+	if err != nil {
+		return fmt.Errorf(errReadEnvironment, err)
+	}
+
+	removeNestedKey(oldValues, strings.Split(ref.GetRemoteKey(), "."))
+
+	updatePayload := &esc.EnvironmentDefinition{
+		Values: &esc.EnvironmentDefinitionValues{
+			AdditionalProperties: oldValues,
+		},
+	}
+	_, err = c.escClient.UpdateEnvironment(c.authCtx, c.organization, environment, updatePayload)
+	if err != nil {
+		return fmt.Errorf(errDeleteSecret, err)
+	}
+
+	return nil
 }
 
 func (c *client) Validate() (esv1beta1.ValidationResult, error) {
@@ -158,38 +301,135 @@ func GetMapFromInterface(i interface{}) (map[string][]byte, error) {
 }
 
 func (c *client) GetSecretMap(_ context.Context, ref esv1beta1.ExternalSecretDataRemoteRef) (map[string][]byte, error) {
-	env, err := c.escClient.OpenEnvironment(c.authCtx, c.organization, c.environment)
+	environments, err := c.environmentsForRef(ref)
 	if err != nil {
 		return nil, err
 	}
 
-	value, _, err := c.escClient.ReadEnvironmentProperty(c.authCtx, c.organization, c.environment, env.GetId(), ref.Key)
-	if err != nil {
-		return nil, err
-	}
+	var lastErr error
+	for _, environment := range environments {
+		entry, err := c.openEnvironment(environment)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-	kv, _ := GetMapFromInterface(value.GetValue())
-	secretData := make(map[string][]byte)
-	for k, v := range kv {
-		byteValue, err := utils.GetByteValue(v)
+		value, ok := getNestedValue(entry.values, strings.Split(ref.Key, "."))
+		if !ok {
+			lastErr = fmt.Errorf(errKeyNotFound, ref.Key)
+			continue
+		}
+
+		kv, err := GetMapFromInterface(value)
 		if err != nil {
 			return nil, err
 		}
-		val := esc.Value{}
-		err = val.UnmarshalJSON(byteValue)
+		return kv, nil
+	}
+	return nil, lastErr
+}
+
+func (c *client) GetAllSecrets(_ context.Context, ref esv1beta1.ExternalSecretFind) (map[string][]byte, error) {
+	var nameRegexp *regexp.Regexp
+	if ref.Name != nil && ref.Name.RegExp != "" {
+		var err error
+		nameRegexp, err = regexp.Compile(ref.Name.RegExp)
 		if err != nil {
 			return nil, err
 		}
-		secretData[k], err = utils.GetByteValue(val.Value)
+	}
+
+	// environments are opened in composition order; the first environment to
+	// define a given key wins, mirroring the precedence GetSecret/GetSecretMap
+	// apply when resolving a single key.
+	secretData := make(map[string][]byte)
+	for _, environment := range c.environments {
+		if len(ref.Tags) > 0 {
+			matched, err := c.environmentMatchesTags(environment, ref.Tags)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		entry, err := c.openEnvironment(environment)
 		if err != nil {
-			return nil, fmt.Errorf(errUnableToGetValues, k, err)
+			return nil, fmt.Errorf(errReadEnvironment, err)
+		}
+
+		flat := make(map[string]interface{})
+		flattenMap("", entry.values, flat)
+
+		if err := collectMatches(secretData, flat, ref, nameRegexp); err != nil {
+			return nil, err
 		}
 	}
 	return secretData, nil
 }
 
-func (c *client) GetAllSecrets(_ context.Context, _ esv1beta1.ExternalSecretFind) (map[string][]byte, error) {
-	return nil, errors.New(errGettingAllSecretsNotSupported)
+// collectMatches filters a single environment's flattened key/value map
+// against ref and inserts newly-matching keys into secretData. Keys already
+// present in secretData are left untouched, so that across multiple
+// environments the first one to define a key wins.
+func collectMatches(secretData map[string][]byte, flat map[string]interface{}, ref esv1beta1.ExternalSecretFind, nameRegexp *regexp.Regexp) error {
+	for key, value := range flat {
+		if ref.Path != nil && !strings.HasPrefix(key, *ref.Path) {
+			continue
+		}
+		if nameRegexp != nil && !nameRegexp.MatchString(key) {
+			continue
+		}
+		if _, exists := secretData[key]; exists {
+			continue
+		}
+		byteValue, err := utils.GetByteValue(value)
+		if err != nil {
+			return fmt.Errorf(errUnableToGetValues, key, err)
+		}
+		secretData[key] = byteValue
+	}
+	return nil
+}
+
+// flattenMap inverts the dotted-path nesting applied by mergeMaps, turning a
+// decoded environment values map into a flat map of dotted keys to leaf values.
+func flattenMap(prefix string, in map[string]interface{}, out map[string]interface{}) {
+	for key, value := range in {
+		flatKey := key
+		if prefix != "" {
+			flatKey = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenMap(flatKey, nested, out)
+			continue
+		}
+		out[flatKey] = value
+	}
+}
+
+// environmentMatchesTags reports whether the named Pulumi ESC environment
+// carries every one of the requested tags. Pulumi ESC tags are set on the
+// environment itself, so a match applies to every key within it.
+func (c *client) environmentMatchesTags(environment string, tags map[string]string) (bool, error) {
+	envMeta, _, err := c.escClient.GetEnvironment(c.authCtx, c.organization, environment)
+	if err != nil {
+		return false, fmt.Errorf(errReadEnvironment, err)
+	}
+	return tagsMatch(envMeta.GetTags(), tags), nil
+}
+
+// tagsMatch reports whether envTags contains every key/value pair in wanted,
+// consistent with the AND semantics other ESO providers apply to
+// ExternalSecretFind.Tags.
+func tagsMatch(envTags, wanted map[string]string) bool {
+	for wantKey, wantValue := range wanted {
+		if gotValue, ok := envTags[wantKey]; !ok || gotValue != wantValue {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *client) Close(context.Context) error {
@@ -0,0 +1,229 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pulumi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	esv1beta1 "github.com/external-secrets/external-secrets/apis/externalsecrets/v1beta1"
+)
+
+func TestGetNestedValue(t *testing.T) {
+	m := map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "hunter2",
+		},
+		"flat": "value",
+	}
+
+	tests := map[string]struct {
+		keys  []string
+		want  interface{}
+		found bool
+	}{
+		"top level key":       {keys: []string{"flat"}, want: "value", found: true},
+		"nested key":          {keys: []string{"db", "password"}, want: "hunter2", found: true},
+		"missing top level":   {keys: []string{"missing"}, found: false},
+		"missing nested":      {keys: []string{"db", "missing"}, found: false},
+		"nested path on leaf": {keys: []string{"flat", "password"}, found: false},
+		"empty keys":          {keys: []string{}, found: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := getNestedValue(m, tc.keys)
+			if ok != tc.found {
+				t.Fatalf("expected found=%v, got %v", tc.found, ok)
+			}
+			if ok && !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRemoveNestedKey(t *testing.T) {
+	m := map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"user":     "admin",
+		},
+		"flat": "value",
+	}
+
+	removeNestedKey(m, []string{"db", "password"})
+
+	if _, ok := getNestedValue(m, []string{"db", "password"}); ok {
+		t.Fatalf("expected db.password to be removed")
+	}
+	if _, ok := getNestedValue(m, []string{"db", "user"}); !ok {
+		t.Fatalf("expected sibling key db.user to survive removal")
+	}
+	if _, ok := getNestedValue(m, []string{"flat"}); !ok {
+		t.Fatalf("expected unrelated top level key to survive removal")
+	}
+}
+
+func TestMergeMaps(t *testing.T) {
+	existing := map[string]interface{}{
+		"db.user":     "admin",
+		"db.password": "old",
+	}
+	update := map[string]interface{}{
+		"db.password": "new",
+	}
+
+	merged := mergeMaps(existing, update)
+
+	db, ok := merged["db"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected db to be a nested map, got %#v", merged["db"])
+	}
+	if db["password"] != "new" {
+		t.Fatalf("expected update to overwrite existing value, got %v", db["password"])
+	}
+	if db["user"] != "admin" {
+		t.Fatalf("expected untouched sibling key to be preserved, got %v", db["user"])
+	}
+}
+
+func TestFlattenMap(t *testing.T) {
+	in := map[string]interface{}{
+		"flat": "value",
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"user":     "admin",
+		},
+	}
+
+	out := make(map[string]interface{})
+	flattenMap("", in, out)
+
+	want := map[string]interface{}{
+		"flat":        "value",
+		"db.password": "hunter2",
+		"db.user":     "admin",
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("expected %#v, got %#v", want, out)
+	}
+}
+
+func TestTagsMatch(t *testing.T) {
+	envTags := map[string]string{
+		"env":  "prod",
+		"team": "platform",
+	}
+
+	tests := map[string]struct {
+		wanted map[string]string
+		want   bool
+	}{
+		"single matching tag":       {wanted: map[string]string{"env": "prod"}, want: true},
+		"all tags match":            {wanted: map[string]string{"env": "prod", "team": "platform"}, want: true},
+		"one of several mismatches": {wanted: map[string]string{"env": "prod", "team": "other"}, want: false},
+		"missing key":               {wanted: map[string]string{"missing": "x"}, want: false},
+		"no tags requested":         {wanted: map[string]string{}, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tagsMatch(envTags, tc.wanted); got != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEnvironmentsForRef(t *testing.T) {
+	c := &client{environments: []string{"prod", "staging"}}
+
+	t.Run("no version uses the full composition", func(t *testing.T) {
+		got, err := c.environmentsForRef(esv1beta1.ExternalSecretDataRemoteRef{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, []string{"prod", "staging"}) {
+			t.Fatalf("expected full environment list, got %v", got)
+		}
+	})
+
+	t.Run("version matching a configured environment overrides it", func(t *testing.T) {
+		got, err := c.environmentsForRef(esv1beta1.ExternalSecretDataRemoteRef{Version: "staging"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, []string{"staging"}) {
+			t.Fatalf("expected only the overridden environment, got %v", got)
+		}
+	})
+
+	t.Run("version outside the configured list is rejected", func(t *testing.T) {
+		_, err := c.environmentsForRef(esv1beta1.ExternalSecretDataRemoteRef{Version: "some-other-org-env"})
+		if err == nil {
+			t.Fatalf("expected an error for an environment outside the store's configured list")
+		}
+	})
+}
+
+func TestCollectMatchesFirstEnvironmentWins(t *testing.T) {
+	secretData := make(map[string][]byte)
+
+	// first (highest-precedence) environment.
+	err := collectMatches(secretData, map[string]interface{}{
+		"db.password": "from-first",
+	}, esv1beta1.ExternalSecretFind{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// second environment defines the same key plus a new one.
+	err = collectMatches(secretData, map[string]interface{}{
+		"db.password": "from-second",
+		"db.user":     "from-second",
+	}, esv1beta1.ExternalSecretFind{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := string(secretData["db.password"]); got != "from-first" {
+		t.Fatalf("expected first environment's value to win, got %q", got)
+	}
+	if got := string(secretData["db.user"]); got != "from-second" {
+		t.Fatalf("expected key only defined by the second environment to be included, got %q", got)
+	}
+}
+
+func TestIsNotFoundError(t *testing.T) {
+	tests := map[string]struct {
+		resp *http.Response
+		want bool
+	}{
+		"nil response": {resp: nil, want: false},
+		"404":          {resp: &http.Response{StatusCode: http.StatusNotFound}, want: true},
+		"500":          {resp: &http.Response{StatusCode: http.StatusInternalServerError}, want: false},
+		"200":          {resp: &http.Response{StatusCode: http.StatusOK}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := isNotFoundError(tc.resp); got != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}